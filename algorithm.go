@@ -0,0 +1,220 @@
+package crypto_utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+)
+
+// KeyAlgorithm selects which key type NewCryptoUtilsWithAlgorithm generates
+// and signs with. Its value is also stored as a one-byte prefix on every
+// base64-encoded key so Base64ToPublicKey and friends can auto-detect the
+// algorithm of a key they didn't generate themselves.
+type KeyAlgorithm byte
+
+const (
+	// RSA2048 generates 2048-bit RSA keys (the historical default).
+	RSA2048 KeyAlgorithm = iota + 1
+	// RSA3072 generates 3072-bit RSA keys.
+	RSA3072
+	// RSA4096 generates 4096-bit RSA keys.
+	RSA4096
+	// EcdsaP256 generates NIST P-256 ECDSA keys, signed with ECDSA and
+	// encrypted with ECIES (see ecies.go).
+	EcdsaP256
+	// Ed25519 generates Ed25519 keys. Signing only: Ed25519 has no native
+	// Diffie-Hellman, so EncryptWithPublicKey/DecryptWithPrivateKey are not
+	// supported for this algorithm.
+	Ed25519
+)
+
+func (a KeyAlgorithm) String() string {
+	switch a {
+	case RSA2048:
+		return "RSA2048"
+	case RSA3072:
+		return "RSA3072"
+	case RSA4096:
+		return "RSA4096"
+	case EcdsaP256:
+		return "EcdsaP256"
+	case Ed25519:
+		return "Ed25519"
+	default:
+		return fmt.Sprintf("KeyAlgorithm(%d)", byte(a))
+	}
+}
+
+func (a KeyAlgorithm) rsaBits() int {
+	switch a {
+	case RSA2048:
+		return 2048
+	case RSA3072:
+		return 3072
+	case RSA4096:
+		return 4096
+	default:
+		return 0
+	}
+}
+
+// generateKeyPair creates a key pair for alg and returns both halves as a
+// one-byte algorithm tag followed by the DER (or, for Ed25519, raw) key
+// encoding, base64-encoded.
+func generateKeyPair(alg KeyAlgorithm) (privateKeyB64 string, publicKeyB64 string, err error) {
+	switch alg {
+	case RSA2048, RSA3072, RSA4096:
+		key, err := rsa.GenerateKey(rand.Reader, alg.rsaBits())
+		if err != nil {
+			return "", "", err
+		}
+		return encodeTagged(alg, x509.MarshalPKCS1PrivateKey(key)),
+			encodeTagged(alg, x509.MarshalPKCS1PublicKey(&key.PublicKey)), nil
+
+	case EcdsaP256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return "", "", err
+		}
+		privateDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return "", "", err
+		}
+		publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			return "", "", err
+		}
+		return encodeTagged(alg, privateDER), encodeTagged(alg, publicDER), nil
+
+	case Ed25519:
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", "", err
+		}
+		return encodeTagged(alg, privateKey), encodeTagged(alg, publicKey), nil
+
+	default:
+		return "", "", fmt.Errorf("crypto_utils: unsupported key algorithm %s", alg)
+	}
+}
+
+// encodeTagged prepends alg as a one-byte prefix to der and base64-encodes
+// the result.
+func encodeTagged(alg KeyAlgorithm, der []byte) string {
+	tagged := make([]byte, 1+len(der))
+	tagged[0] = byte(alg)
+	copy(tagged[1:], der)
+	return base64.StdEncoding.EncodeToString(tagged)
+}
+
+// decodeTagged base64-decodes keyB64 and splits off its algorithm tag.
+func decodeTagged(keyB64 string) (KeyAlgorithm, []byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(keyB64)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(raw) < 1 {
+		return 0, nil, fmt.Errorf("crypto_utils: key is too short to contain an algorithm tag")
+	}
+	return KeyAlgorithm(raw[0]), raw[1:], nil
+}
+
+// parsePublicKeyB64 decodes a tagged base64 public key into its concrete
+// type: *rsa.PublicKey, *ecdsa.PublicKey, or ed25519.PublicKey.
+func parsePublicKeyB64(publicKeyB64 string) (KeyAlgorithm, crypto.PublicKey, error) {
+	alg, der, err := decodeTagged(publicKeyB64)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	switch alg {
+	case RSA2048, RSA3072, RSA4096:
+		pub, err := x509.ParsePKCS1PublicKey(der)
+		return alg, pub, err
+
+	case EcdsaP256:
+		pub, err := x509.ParsePKIXPublicKey(der)
+		if err != nil {
+			return 0, nil, err
+		}
+		ecPub, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return 0, nil, fmt.Errorf("crypto_utils: expected an ECDSA public key, got %T", pub)
+		}
+		return alg, ecPub, nil
+
+	case Ed25519:
+		return alg, ed25519.PublicKey(der), nil
+
+	default:
+		return 0, nil, fmt.Errorf("crypto_utils: unsupported key algorithm tag %d", byte(alg))
+	}
+}
+
+// parsePrivateKeyAsSigner decodes a tagged base64 private key into a
+// crypto.Signer: *rsa.PrivateKey, *ecdsa.PrivateKey, or ed25519.PrivateKey.
+func parsePrivateKeyAsSigner(privateKeyB64 string) (KeyAlgorithm, crypto.Signer, error) {
+	alg, der, err := decodeTagged(privateKeyB64)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	switch alg {
+	case RSA2048, RSA3072, RSA4096:
+		key, err := x509.ParsePKCS1PrivateKey(der)
+		return alg, key, err
+
+	case EcdsaP256:
+		key, err := x509.ParseECPrivateKey(der)
+		return alg, key, err
+
+	case Ed25519:
+		return alg, ed25519.PrivateKey(der), nil
+
+	default:
+		return 0, nil, fmt.Errorf("crypto_utils: unsupported key algorithm tag %d", byte(alg))
+	}
+}
+
+// signWithSigner signs data under signer, dispatching to the signature
+// scheme appropriate for its concrete type. RSA and ECDSA sign a SHA-256
+// digest; Ed25519 signs the message directly, since it does not support
+// pre-hashing.
+func signWithSigner(signer crypto.Signer, data []byte) ([]byte, error) {
+	if key, ok := signer.(ed25519.PrivateKey); ok {
+		return ed25519.Sign(key, data), nil
+	}
+
+	digest := sha256Sum(data)
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest)
+	case *ecdsa.PrivateKey:
+		return ecdsa.SignASN1(rand.Reader, key, digest)
+	default:
+		return nil, fmt.Errorf("crypto_utils: unsupported signer type %T", signer)
+	}
+}
+
+// verifyWithPublicKey verifies signature over data under publicKey,
+// dispatching on its concrete type to match signWithSigner.
+func verifyWithPublicKey(publicKey crypto.PublicKey, data []byte, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		digest := sha256Sum(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		digest := sha256Sum(data)
+		return ecdsa.VerifyASN1(key, digest, signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, signature)
+	default:
+		return false
+	}
+}
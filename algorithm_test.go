@@ -0,0 +1,77 @@
+package crypto_utils_test
+
+import (
+	"crypto/rsa"
+	"testing"
+
+	"github.com/sudhi001/crypto_utils"
+)
+
+func TestEcdsaP256SignAndEncryptRoundTrip(t *testing.T) {
+	crypto := crypto_utils.NewCryptoUtilsWithAlgorithm(crypto_utils.EcdsaP256)
+
+	privateKey, publicKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate ECDSA P-256 key pair: %v", err)
+	}
+
+	message := []byte("Hello from the ECDSA P-256 flow!")
+	signature := crypto.SignWithPrivateKey(privateKey, message)
+	if !crypto.VerifyWithPublicKey(publicKey, message, signature) {
+		t.Fatalf("ECDSA P-256 signature failed to verify")
+	}
+
+	parsedPublicKey, err := crypto.Base64ToPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("Failed to parse ECDSA P-256 public key: %v", err)
+	}
+
+	plaintext := []byte("secret payload")
+	encrypted := crypto.EncryptWithPublicKey(parsedPublicKey, plaintext)
+	decrypted := crypto.DecryptWithPrivateKey(privateKey, encrypted)
+	if string(decrypted) != string(plaintext) {
+		t.Fatalf("ECIES round trip mismatch. Expected: %s, Got: %s", plaintext, decrypted)
+	}
+}
+
+func TestEd25519SignAndVerify(t *testing.T) {
+	crypto := crypto_utils.NewCryptoUtilsWithAlgorithm(crypto_utils.Ed25519)
+
+	privateKey, publicKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key pair: %v", err)
+	}
+
+	message := []byte("Hello from the Ed25519 flow!")
+	signature := crypto.SignWithPrivateKey(privateKey, message)
+	if !crypto.VerifyWithPublicKey(publicKey, message, signature) {
+		t.Fatalf("Ed25519 signature failed to verify")
+	}
+
+	if crypto.VerifyWithPublicKey(publicKey, []byte("tampered"), signature) {
+		t.Fatalf("Ed25519 signature verified against the wrong message")
+	}
+}
+
+func TestGenerateKeyPairRespectsRSAVariant(t *testing.T) {
+	crypto := crypto_utils.NewCryptoUtilsWithAlgorithm(crypto_utils.RSA3072)
+
+	privateKey, publicKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate RSA-3072 key pair: %v", err)
+	}
+
+	parsedPublicKey, err := crypto.Base64ToPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("Failed to parse RSA-3072 public key: %v", err)
+	}
+	if bits := parsedPublicKey.(*rsa.PublicKey).N.BitLen(); bits != 3072 {
+		t.Fatalf("expected a 3072-bit RSA key, got %d bits", bits)
+	}
+
+	message := []byte("still RSA under the hood")
+	signature := crypto.SignWithPrivateKey(privateKey, message)
+	if !crypto.VerifyWithPublicKey(publicKey, message, signature) {
+		t.Fatalf("RSA-3072 signature failed to verify")
+	}
+}
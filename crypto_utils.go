@@ -0,0 +1,198 @@
+// Package crypto_utils provides a small set of RSA/ECDSA/Ed25519 + AES-GCM
+// helpers used to exchange encrypted payloads between this Go service and
+// the companion Flutter client. The API intentionally favors simple
+// byte/base64 shapes so the same primitives are trivial to reproduce with
+// Dart's pointycastle.
+package crypto_utils
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// CryptoUtils bundles the key generation, signing, and encryption
+// operations used across the encryption flow. It is safe for concurrent
+// use. The zero value is not usable; construct one with NewCryptoUtils or
+// NewCryptoUtilsWithAlgorithm.
+type CryptoUtils struct {
+	algorithm KeyAlgorithm
+}
+
+// NewCryptoUtils returns a CryptoUtils that generates RSA-2048 keys, the
+// historical default.
+func NewCryptoUtils() *CryptoUtils {
+	return &CryptoUtils{algorithm: RSA2048}
+}
+
+// NewCryptoUtilsWithAlgorithm returns a CryptoUtils whose GenerateKeyPair
+// generates keys for alg. Signing and encryption auto-detect a key's
+// algorithm from its base64 tag, so they work the same regardless of which
+// CryptoUtils generated the key.
+func NewCryptoUtilsWithAlgorithm(alg KeyAlgorithm) *CryptoUtils {
+	return &CryptoUtils{algorithm: alg}
+}
+
+// GenerateRandomBytes returns size cryptographically random bytes, suitable
+// for AES keys or nonces.
+func (c *CryptoUtils) GenerateRandomBytes(size int) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// GenerateRSAKeyPair creates a 2048-bit RSA key pair regardless of c's
+// configured algorithm, kept for callers that only ever spoke RSA. New code
+// should prefer GenerateKeyPair.
+func (c *CryptoUtils) GenerateRSAKeyPair() (privateKeyB64 string, publicKeyB64 string, err error) {
+	return generateKeyPair(RSA2048)
+}
+
+// GenerateKeyPair creates a key pair using c's configured KeyAlgorithm and
+// returns both halves as base64, each tagged with a one-byte algorithm
+// marker that Base64ToPublicKey and the sign/verify/encrypt/decrypt helpers
+// use to auto-detect the key type.
+func (c *CryptoUtils) GenerateKeyPair() (privateKeyB64 string, publicKeyB64 string, err error) {
+	return generateKeyPair(c.algorithm)
+}
+
+// Base64ToPublicKey decodes a tagged base64 public key produced by
+// GenerateKeyPair/GenerateRSAKeyPair into its concrete type (*rsa.PublicKey,
+// *ecdsa.PublicKey, or ed25519.PublicKey).
+func (c *CryptoUtils) Base64ToPublicKey(publicKeyB64 string) (crypto.PublicKey, error) {
+	_, publicKey, err := parsePublicKeyB64(publicKeyB64)
+	return publicKey, err
+}
+
+// EncryptWithAES encrypts plaintext with AES-GCM under key, returning the
+// base64-encoded ciphertext and the randomly generated nonce. It panics if
+// key is not a valid AES key size; see TryEncryptWithAES for an
+// error-returning equivalent.
+func (c *CryptoUtils) EncryptWithAES(key []byte, plaintext []byte) (string, []byte) {
+	ciphertext, nonce, err := c.TryEncryptWithAES(key, plaintext)
+	if err != nil {
+		panic(err)
+	}
+	return ciphertext, nonce
+}
+
+// DecryptWithAES reverses EncryptWithAES. ciphertext is the base64-encoded
+// string produced by EncryptWithAES, passed as raw bytes. It panics if key,
+// nonce, or ciphertext don't match (e.g. tampering or the wrong key); see
+// TryDecryptWithAES for an error-returning equivalent.
+func (c *CryptoUtils) DecryptWithAES(key []byte, ciphertext []byte, nonce []byte) string {
+	plaintext, err := c.TryDecryptWithAES(key, ciphertext, nonce)
+	if err != nil {
+		panic(err)
+	}
+	return plaintext
+}
+
+// EncryptWithPublicKey encrypts data under publicKey, returning a
+// base64-encoded ciphertext. RSA keys use RSA-OAEP(SHA-256); ECDSA P-256
+// keys use ECIES (see ecies.go). Ed25519 keys are signing-only and are not
+// accepted. It panics on encryption failure or an unsupported key type.
+func (c *CryptoUtils) EncryptWithPublicKey(publicKey crypto.PublicKey, data []byte) string {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		ciphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, data, nil)
+		if err != nil {
+			panic(err)
+		}
+		return base64.StdEncoding.EncodeToString(ciphertext)
+
+	case *ecdsa.PublicKey:
+		ciphertext, err := eciesEncrypt(key, data)
+		if err != nil {
+			panic(err)
+		}
+		return ciphertext
+
+	default:
+		panic(fmt.Errorf("crypto_utils: unsupported public key type %T for encryption", publicKey))
+	}
+}
+
+// DecryptWithPrivateKey reverses EncryptWithPublicKey given the tagged
+// base64 private key and the base64-encoded ciphertext. It panics on
+// decryption failure or an unsupported key type.
+func (c *CryptoUtils) DecryptWithPrivateKey(privateKeyB64 string, encryptedB64 string) []byte {
+	alg, signer, err := parsePrivateKeyAsSigner(privateKeyB64)
+	if err != nil {
+		panic(err)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encryptedB64)
+	if err != nil {
+		panic(err)
+	}
+
+	switch alg {
+	case RSA2048, RSA3072, RSA4096:
+		privateKey := signer.(*rsa.PrivateKey)
+		plaintext, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, ciphertext, nil)
+		if err != nil {
+			panic(err)
+		}
+		return plaintext
+
+	case EcdsaP256:
+		privateKey := signer.(*ecdsa.PrivateKey)
+		plaintext, err := eciesDecrypt(privateKey, ciphertext)
+		if err != nil {
+			panic(err)
+		}
+		return plaintext
+
+	default:
+		panic(fmt.Errorf("crypto_utils: unsupported key algorithm %s for decryption", alg))
+	}
+}
+
+// SignWithPrivateKey signs the SHA-256 digest of data (or, for Ed25519, data
+// itself) with the tagged base64 private key, returning the signature
+// base64-encoded. It panics on signing failure; see TrySignWithPrivateKey
+// for an error-returning equivalent.
+func (c *CryptoUtils) SignWithPrivateKey(privateKeyB64 string, data []byte) string {
+	signature, err := c.TrySignWithPrivateKey(privateKeyB64, data)
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+// VerifyWithPublicKey verifies a base64-encoded SignWithPrivateKey signature
+// over data. It returns false (rather than an error) for any mismatch,
+// including a malformed key or signature; see TryVerifyWithPublicKey to
+// distinguish a malformed input from a failed verification.
+func (c *CryptoUtils) VerifyWithPublicKey(publicKeyB64 string, data []byte, signatureB64 string) bool {
+	ok, err := c.TryVerifyWithPublicKey(publicKeyB64, data, signatureB64)
+	if err != nil {
+		return false
+	}
+	return ok
+}
+
+// sha256Sum returns the SHA-256 digest of data as a slice, for callers that
+// don't want to juggle the [32]byte array crypto/sha256 returns.
+func sha256Sum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// aesGCM builds an AES-GCM cipher from key, used throughout this package.
+func aesGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
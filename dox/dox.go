@@ -0,0 +1,215 @@
+// Package dox provides struct-tag-driven, field-wise document encryption on
+// top of crypto_utils' RSA/ECIES + AES-GCM primitives. Fields tagged
+// `crypto:"plain"` stay queryable in the clear, fields tagged
+// `crypto:"hash"` are replaced by their SHA-256 hex digest so they remain
+// searchable without exposing the value, and fields tagged `crypto:"encrypt"`
+// are moved into an AES-GCM encrypted blob. Untagged fields are left alone.
+package dox
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/sudhi001/crypto_utils"
+)
+
+const tagName = "crypto"
+
+// Doc is the encrypted-at-rest representation of a tagged struct: an AES
+// key wrapped for the recipient's public key (RSA-OAEP for RSA keys, ECIES
+// for ECDSA P-256 keys), the plaintext/hashed queryable Fields, and an
+// AES-GCM Blob holding the JSON of the encrypted fields.
+type Doc struct {
+	EncryptedKey string                 `json:"encrypted_key"`
+	Fields       map[string]interface{} `json:"fields,omitempty"`
+	Nonce        string                 `json:"nonce"`
+	Blob         string                 `json:"blob"`
+}
+
+// Encrypt walks v's fields once, splitting them by their `crypto` struct
+// tag, and returns a Doc: `crypto:"plain"` fields are copied into Fields
+// as-is, `crypto:"hash"` fields are replaced by their SHA-256 hex digest in
+// Fields, and `crypto:"encrypt"` fields are JSON-marshaled together and
+// sealed in Blob under a fresh AES-256 key that is itself wrapped for pub.
+// v must be a non-nil pointer to a struct. pub must be an *rsa.PublicKey or
+// an *ecdsa.PublicKey; any other type (including Ed25519, which is
+// signing-only) returns an error rather than panicking.
+func Encrypt(pub crypto.PublicKey, v interface{}) (*Doc, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return nil, fmt.Errorf("dox: unsupported public key type %T, must be *rsa.PublicKey or *ecdsa.PublicKey", pub)
+	}
+
+	fields, err := reflectFields(v)
+	if err != nil {
+		return nil, err
+	}
+
+	plainFields := make(map[string]interface{}, len(fields))
+	encryptedFields := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		switch f.mode {
+		case modePlain:
+			plainFields[f.name] = f.value.Interface()
+		case modeHash:
+			plainFields[f.name] = hashValue(f.value.Interface())
+		case modeEncrypt:
+			encryptedFields[f.name] = f.value.Interface()
+		}
+	}
+
+	blobPlaintext, err := json.Marshal(encryptedFields)
+	if err != nil {
+		return nil, fmt.Errorf("dox: marshal encrypted fields: %w", err)
+	}
+
+	crypto := crypto_utils.NewCryptoUtils()
+
+	aesKey, err := crypto.GenerateRandomBytes(32)
+	if err != nil {
+		return nil, fmt.Errorf("dox: generate AES key: %w", err)
+	}
+
+	blobB64, nonce := crypto.EncryptWithAES(aesKey, blobPlaintext)
+
+	encryptedKey := crypto.EncryptWithPublicKey(pub, aesKey)
+
+	return &Doc{
+		EncryptedKey: encryptedKey,
+		Fields:       plainFields,
+		Nonce:        base64.StdEncoding.EncodeToString(nonce),
+		Blob:         blobB64,
+	}, nil
+}
+
+// Decrypt unwraps the AES key in doc with priv, opens Blob, and writes both
+// the plaintext and decrypted-blob fields back onto v. Fields tagged
+// `crypto:"hash"` are not restored, since only their digest was ever stored.
+// v must be a non-nil pointer to a struct, typically the same type passed
+// to Encrypt.
+func (doc *Doc) Decrypt(priv string, v interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("dox: decrypt: %v", r)
+		}
+	}()
+
+	fields, ferr := reflectFields(v)
+	if ferr != nil {
+		return ferr
+	}
+
+	crypto := crypto_utils.NewCryptoUtils()
+
+	aesKey := crypto.DecryptWithPrivateKey(priv, doc.EncryptedKey)
+
+	nonce, nerr := base64.StdEncoding.DecodeString(doc.Nonce)
+	if nerr != nil {
+		return fmt.Errorf("dox: decode nonce: %w", nerr)
+	}
+
+	blobPlaintext := crypto.DecryptWithAES(aesKey, []byte(doc.Blob), nonce)
+
+	var encryptedFields map[string]interface{}
+	if uerr := json.Unmarshal([]byte(blobPlaintext), &encryptedFields); uerr != nil {
+		return fmt.Errorf("dox: unmarshal encrypted fields: %w", uerr)
+	}
+
+	for _, f := range fields {
+		switch f.mode {
+		case modePlain:
+			if raw, ok := doc.Fields[f.name]; ok {
+				if serr := setField(f.value, raw); serr != nil {
+					return serr
+				}
+			}
+		case modeEncrypt:
+			if raw, ok := encryptedFields[f.name]; ok {
+				if serr := setField(f.value, raw); serr != nil {
+					return serr
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type fieldMode int
+
+const (
+	modePlain fieldMode = iota
+	modeHash
+	modeEncrypt
+)
+
+type taggedField struct {
+	name  string
+	mode  fieldMode
+	value reflect.Value
+}
+
+// reflectFields walks v's struct fields once and returns those carrying a
+// recognized `crypto` tag.
+func reflectFields(v interface{}) ([]taggedField, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("dox: v must be a non-nil pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+
+	var fields []taggedField
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get(tagName)
+		var mode fieldMode
+		switch tag {
+		case "plain":
+			mode = modePlain
+		case "hash":
+			mode = modeHash
+		case "encrypt":
+			mode = modeEncrypt
+		default:
+			continue
+		}
+		if !t.Field(i).IsExported() {
+			return nil, fmt.Errorf("dox: field %s is tagged %q but unexported; crypto tags require an exported field", t.Field(i).Name, tag)
+		}
+		fields = append(fields, taggedField{name: t.Field(i).Name, mode: mode, value: rv.Field(i)})
+	}
+	return fields, nil
+}
+
+// setField JSON round-trips raw into field, giving the same type coercion
+// encoding/json would apply on a direct unmarshal into that field's type.
+func setField(field reflect.Value, raw interface{}) error {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("dox: marshal field value: %w", err)
+	}
+	if !field.CanAddr() {
+		return fmt.Errorf("dox: field %s is not addressable", field.Type())
+	}
+	if err := json.Unmarshal(data, field.Addr().Interface()); err != nil {
+		return fmt.Errorf("dox: unmarshal field value: %w", err)
+	}
+	return nil
+}
+
+// hashValue returns the SHA-256 hex digest of v's JSON representation.
+func hashValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%v", v))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
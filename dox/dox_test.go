@@ -0,0 +1,140 @@
+package dox_test
+
+import (
+	"testing"
+
+	"github.com/sudhi001/crypto_utils"
+	"github.com/sudhi001/crypto_utils/dox"
+)
+
+type patientRecord struct {
+	ID          string  `crypto:"plain"`
+	Email       string  `crypto:"hash"`
+	SSN         string  `crypto:"encrypt"`
+	DiagnosisID int     `crypto:"encrypt"`
+	Balance     float64 `crypto:"encrypt"`
+	internal    string
+}
+
+func TestDocEncryptDecryptRoundTrip(t *testing.T) {
+	crypto := crypto_utils.NewCryptoUtils()
+	privateKeyB64, publicKeyB64, err := crypto.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+	publicKey, err := crypto.Base64ToPublicKey(publicKeyB64)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+
+	record := patientRecord{
+		ID:          "patient-42",
+		Email:       "jane@example.com",
+		SSN:         "078-05-1120",
+		DiagnosisID: 172,
+		Balance:     100.5,
+		internal:    "not tagged, ignored",
+	}
+
+	doc, err := dox.Encrypt(publicKey, &record)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if doc.Fields["ID"] != "patient-42" {
+		t.Fatalf("expected plain field ID to be stored as-is, got %v", doc.Fields["ID"])
+	}
+	if doc.Fields["Email"] == record.Email {
+		t.Fatalf("expected hash field Email to be hashed, not stored in the clear")
+	}
+
+	var decoded patientRecord
+	if err := doc.Decrypt(privateKeyB64, &decoded); err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+
+	if decoded.ID != record.ID {
+		t.Fatalf("plain field mismatch. Expected: %s, Got: %s", record.ID, decoded.ID)
+	}
+	if decoded.SSN != record.SSN {
+		t.Fatalf("encrypted field mismatch. Expected: %s, Got: %s", record.SSN, decoded.SSN)
+	}
+	if decoded.DiagnosisID != record.DiagnosisID {
+		t.Fatalf("encrypted field mismatch. Expected: %d, Got: %d", record.DiagnosisID, decoded.DiagnosisID)
+	}
+	if decoded.Balance != record.Balance {
+		t.Fatalf("encrypted field mismatch. Expected: %v, Got: %v", record.Balance, decoded.Balance)
+	}
+	if decoded.Email == record.Email {
+		t.Fatalf("hash field should not be restored on decrypt")
+	}
+}
+
+type badRecord struct {
+	ID     string `crypto:"plain"`
+	secret string `crypto:"encrypt"`
+}
+
+func TestDocEncryptRejectsUnexportedTaggedField(t *testing.T) {
+	crypto := crypto_utils.NewCryptoUtils()
+	_, publicKeyB64, err := crypto.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+	publicKey, err := crypto.Base64ToPublicKey(publicKeyB64)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+
+	record := badRecord{ID: "patient-1", secret: "should not panic"}
+	if _, err := dox.Encrypt(publicKey, &record); err == nil {
+		t.Fatalf("expected Encrypt to return an error for an unexported tagged field")
+	}
+}
+
+func TestDocEncryptRejectsUnsupportedKeyType(t *testing.T) {
+	ed25519Crypto := crypto_utils.NewCryptoUtilsWithAlgorithm(crypto_utils.Ed25519)
+	_, ed25519PublicKeyB64, err := ed25519Crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key pair: %v", err)
+	}
+	ed25519PublicKey, err := ed25519Crypto.Base64ToPublicKey(ed25519PublicKeyB64)
+	if err != nil {
+		t.Fatalf("Failed to parse Ed25519 public key: %v", err)
+	}
+
+	record := patientRecord{ID: "patient-1", SSN: "000-00-0000"}
+	if _, err := dox.Encrypt(ed25519PublicKey, &record); err == nil {
+		t.Fatalf("expected Encrypt to return an error for an Ed25519 public key")
+	}
+	if _, err := dox.Encrypt(nil, &record); err == nil {
+		t.Fatalf("expected Encrypt to return an error for a nil public key")
+	}
+}
+
+func TestDocDecryptFailsWithWrongKey(t *testing.T) {
+	crypto := crypto_utils.NewCryptoUtils()
+	_, publicKeyB64, err := crypto.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+	publicKey, err := crypto.Base64ToPublicKey(publicKeyB64)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+
+	wrongPrivateKeyB64, _, err := crypto.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+
+	record := patientRecord{ID: "patient-1", SSN: "000-00-0000"}
+	doc, err := dox.Encrypt(publicKey, &record)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	var decoded patientRecord
+	if err := doc.Decrypt(wrongPrivateKeyB64, &decoded); err == nil {
+		t.Fatalf("expected Decrypt to fail with the wrong private key")
+	}
+}
@@ -0,0 +1,127 @@
+package crypto_utils
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// eciesInfo is the HKDF "info" parameter binding derived keys to this
+// scheme, so a shared secret can never be reused across unrelated purposes.
+const eciesInfo = "crypto_utils ecies v1"
+
+// eciesNonceSize and the P-256 uncompressed point size (1 + 32 + 32 bytes)
+// give eciesEncrypt's output a fixed, self-describing layout:
+// ephemeralPublicKey || nonce || ciphertext+tag.
+const (
+	eciesP256PointSize = 65
+	eciesNonceSize     = 12
+)
+
+// eciesEncrypt hybrid-encrypts plaintext for an ECDSA P-256 recipient: an
+// ephemeral P-256 key agrees on a shared secret with publicKey via ECDH,
+// HKDF-SHA256 stretches that secret into an AES-256 key, and AES-GCM seals
+// plaintext under it. The ephemeral public key is included as both a
+// prefix of the output and the AEAD's additional data.
+func eciesEncrypt(publicKey *ecdsa.PublicKey, plaintext []byte) (string, error) {
+	recipient, err := publicKey.ECDH()
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: recipient key is not usable for ECDH: %w", err)
+	}
+
+	ephemeral, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: generate ephemeral key: %w", err)
+	}
+
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: ECDH: %w", err)
+	}
+
+	ephemeralPublicKey := ephemeral.PublicKey().Bytes()
+
+	aesKey, err := deriveECIESKey(shared, ephemeralPublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := aesGCM(aesKey)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("crypto_utils: generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nil, nonce, plaintext, ephemeralPublicKey)
+
+	out := make([]byte, 0, len(ephemeralPublicKey)+len(nonce)+len(sealed))
+	out = append(out, ephemeralPublicKey...)
+	out = append(out, nonce...)
+	out = append(out, sealed...)
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// eciesDecrypt reverses eciesEncrypt. raw is the already base64-decoded
+// ephemeralPublicKey || nonce || ciphertext+tag layout eciesEncrypt produces.
+func eciesDecrypt(privateKey *ecdsa.PrivateKey, raw []byte) ([]byte, error) {
+	if len(raw) < eciesP256PointSize+eciesNonceSize {
+		return nil, fmt.Errorf("crypto_utils: ciphertext too short for ECIES")
+	}
+
+	ephemeralPublicKey := raw[:eciesP256PointSize]
+	nonce := raw[eciesP256PointSize : eciesP256PointSize+eciesNonceSize]
+	sealed := raw[eciesP256PointSize+eciesNonceSize:]
+
+	self, err := privateKey.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: private key is not usable for ECDH: %w", err)
+	}
+
+	ephemeral, err := ecdh.P256().NewPublicKey(ephemeralPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: parse ephemeral public key: %w", err)
+	}
+
+	shared, err := self.ECDH(ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: ECDH: %w", err)
+	}
+
+	aesKey, err := deriveECIESKey(shared, ephemeralPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := aesGCM(aesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, sealed, ephemeralPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: open payload: %w", err)
+	}
+	return plaintext, nil
+}
+
+// deriveECIESKey stretches an ECDH shared secret into an AES-256 key via
+// HKDF-SHA256, salted with the ephemeral public key so each encryption uses
+// an independent key even when the same recipient is reused.
+func deriveECIESKey(shared []byte, salt []byte) ([]byte, error) {
+	kdf := hkdf.New(sha256.New, shared, salt, []byte(eciesInfo))
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, fmt.Errorf("crypto_utils: derive key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,445 @@
+package crypto_utils
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Header is a JOSE protected header (RFC 7515 §4 / RFC 7516 §4). Extra holds
+// any additional members beyond the well-known ones, so callers can stash
+// application-specific claims (e.g. "cty") without losing them on decode.
+type Header struct {
+	Alg   string                 `json:"alg"`
+	Enc   string                 `json:"enc,omitempty"`
+	Kid   string                 `json:"kid,omitempty"`
+	Extra map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON flattens Extra into the top-level object alongside the
+// well-known members, matching how a JOSE protected header is encoded.
+func (h Header) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(h.Extra)+3)
+	for k, v := range h.Extra {
+		m[k] = v
+	}
+	m["alg"] = h.Alg
+	if h.Enc != "" {
+		m["enc"] = h.Enc
+	}
+	if h.Kid != "" {
+		m["kid"] = h.Kid
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON recovers the well-known members and places everything else
+// into Extra.
+func (h *Header) UnmarshalJSON(data []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	if alg, ok := m["alg"].(string); ok {
+		h.Alg = alg
+		delete(m, "alg")
+	}
+	if enc, ok := m["enc"].(string); ok {
+		h.Enc = enc
+		delete(m, "enc")
+	}
+	if kid, ok := m["kid"].(string); ok {
+		h.Kid = kid
+		delete(m, "kid")
+	}
+	if len(m) > 0 {
+		h.Extra = m
+	}
+	return nil
+}
+
+// JWE is a parsed JSON Web Encryption message using alg=RSA-OAEP-256 to wrap
+// the content encryption key and enc=A256GCM for the payload.
+type JWE struct {
+	Header       Header
+	EncryptedKey []byte
+	IV           []byte
+	Ciphertext   []byte
+	Tag          []byte
+
+	// rawProtected holds the exact protected-header bytes that were (or will
+	// be) transmitted on the wire. Decrypt must authenticate against these
+	// bytes, not a fresh re-marshal of Header, since RFC 7516 §5.1 step 14
+	// requires the AAD to be the bytes that were actually sent: a JWE from
+	// another JOSE library is not guaranteed to serialize its header the
+	// same way encoding/json does. It is empty only for a JWE built by hand
+	// without going through Encrypt or a ParseJWE* function, in which case
+	// Decrypt falls back to marshaling Header itself.
+	rawProtected []byte
+}
+
+// JWEEncrypter produces JWE messages for a single RSA public key.
+type JWEEncrypter struct {
+	publicKey *rsa.PublicKey
+}
+
+// NewJWEEncrypter returns a JWEEncrypter that wraps content encryption keys
+// under publicKey using RSA-OAEP-256.
+func NewJWEEncrypter(publicKey *rsa.PublicKey) *JWEEncrypter {
+	return &JWEEncrypter{publicKey: publicKey}
+}
+
+// Encrypt generates a random A256GCM content encryption key, encrypts
+// plaintext, and wraps the key with RSA-OAEP-256. header.Alg/Enc are
+// overwritten to "RSA-OAEP-256"/"A256GCM"; any other fields (Kid, Extra) are
+// preserved in the protected header.
+func (e *JWEEncrypter) Encrypt(plaintext []byte, header Header) (*JWE, error) {
+	cek := make([]byte, 32) // A256GCM key size
+	if _, err := rand.Read(cek); err != nil {
+		return nil, fmt.Errorf("crypto_utils: generate content encryption key: %w", err)
+	}
+
+	header.Alg = "RSA-OAEP-256"
+	header.Enc = "A256GCM"
+
+	protected, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: marshal protected header: %w", err)
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, e.publicKey, cek, nil)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: wrap content encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: init content cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: init content AEAD: %w", err)
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("crypto_utils: generate iv: %w", err)
+	}
+
+	// The JOSE AAD for a compact/flattened JWE is the ASCII protected header
+	// as it will appear on the wire (RFC 7516 §5.1 step 14).
+	aad := []byte(base64.RawURLEncoding.EncodeToString(protected))
+	sealed := gcm.Seal(nil, iv, plaintext, aad)
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return &JWE{
+		Header:       header,
+		EncryptedKey: encryptedKey,
+		IV:           iv,
+		Ciphertext:   ciphertext,
+		Tag:          tag,
+		rawProtected: protected,
+	}, nil
+}
+
+// JWEDecrypter opens JWE messages for a single RSA private key.
+type JWEDecrypter struct {
+	privateKey *rsa.PrivateKey
+}
+
+// NewJWEDecrypter returns a JWEDecrypter that unwraps content encryption
+// keys with privateKey.
+func NewJWEDecrypter(privateKey *rsa.PrivateKey) *JWEDecrypter {
+	return &JWEDecrypter{privateKey: privateKey}
+}
+
+// Decrypt unwraps jwe.EncryptedKey with RSA-OAEP-256 and opens the A256GCM
+// payload, returning the plaintext.
+func (d *JWEDecrypter) Decrypt(jwe *JWE) ([]byte, error) {
+	if jwe.Header.Alg != "RSA-OAEP-256" {
+		return nil, fmt.Errorf("crypto_utils: unsupported alg %q", jwe.Header.Alg)
+	}
+	if jwe.Header.Enc != "A256GCM" {
+		return nil, fmt.Errorf("crypto_utils: unsupported enc %q", jwe.Header.Enc)
+	}
+
+	cek, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, d.privateKey, jwe.EncryptedKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: unwrap content encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: init content cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: init content AEAD: %w", err)
+	}
+
+	protected, err := jwe.protectedHeaderBytes()
+	if err != nil {
+		return nil, err
+	}
+	aad := []byte(base64.RawURLEncoding.EncodeToString(protected))
+
+	sealed := append(append([]byte{}, jwe.Ciphertext...), jwe.Tag...)
+	plaintext, err := gcm.Open(nil, jwe.IV, sealed, aad)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: open payload: %w", err)
+	}
+	return plaintext, nil
+}
+
+// DecryptCompact parses and decrypts a compact-serialized JWE in one step.
+func (d *JWEDecrypter) DecryptCompact(compact string) ([]byte, error) {
+	jwe, err := ParseJWECompact(compact)
+	if err != nil {
+		return nil, err
+	}
+	return d.Decrypt(jwe)
+}
+
+// protectedHeaderBytes returns the exact protected-header bytes jwe was
+// sealed under (see the rawProtected field doc), re-marshaling Header only
+// for a JWE built by hand without going through Encrypt or a ParseJWE*
+// function.
+func (jwe *JWE) protectedHeaderBytes() ([]byte, error) {
+	if jwe.rawProtected != nil {
+		return jwe.rawProtected, nil
+	}
+	protected, err := json.Marshal(jwe.Header)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: marshal protected header: %w", err)
+	}
+	return protected, nil
+}
+
+// Compact renders jwe as the five-part compact serialization:
+// header.enckey.iv.ciphertext.tag, each base64url-encoded without padding.
+func (jwe *JWE) Compact() (string, error) {
+	protected, err := jwe.protectedHeaderBytes()
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{
+		base64.RawURLEncoding.EncodeToString(protected),
+		base64.RawURLEncoding.EncodeToString(jwe.EncryptedKey),
+		base64.RawURLEncoding.EncodeToString(jwe.IV),
+		base64.RawURLEncoding.EncodeToString(jwe.Ciphertext),
+		base64.RawURLEncoding.EncodeToString(jwe.Tag),
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// ParseJWECompact parses the five-part compact serialization produced by
+// JWE.Compact.
+func ParseJWECompact(compact string) (*JWE, error) {
+	parts := strings.Split(compact, ".")
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("crypto_utils: compact JWE must have 5 parts, got %d", len(parts))
+	}
+
+	protected, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decode protected header: %w", err)
+	}
+	var header Header
+	if err := json.Unmarshal(protected, &header); err != nil {
+		return nil, fmt.Errorf("crypto_utils: unmarshal protected header: %w", err)
+	}
+
+	encryptedKey, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decode encrypted key: %w", err)
+	}
+	iv, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decode iv: %w", err)
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decode ciphertext: %w", err)
+	}
+	tag, err := base64.RawURLEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decode tag: %w", err)
+	}
+
+	return &JWE{Header: header, EncryptedKey: encryptedKey, IV: iv, Ciphertext: ciphertext, Tag: tag, rawProtected: protected}, nil
+}
+
+// jweJSON is the JWE JSON Serialization (RFC 7516 §7.2), flattened form.
+type jweJSON struct {
+	Protected    string `json:"protected"`
+	EncryptedKey string `json:"encrypted_key"`
+	IV           string `json:"iv"`
+	Ciphertext   string `json:"ciphertext"`
+	Tag          string `json:"tag"`
+}
+
+// JSON renders jwe as the flattened JWE JSON Serialization.
+func (jwe *JWE) JSON() ([]byte, error) {
+	protected, err := jwe.protectedHeaderBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jweJSON{
+		Protected:    base64.RawURLEncoding.EncodeToString(protected),
+		EncryptedKey: base64.RawURLEncoding.EncodeToString(jwe.EncryptedKey),
+		IV:           base64.RawURLEncoding.EncodeToString(jwe.IV),
+		Ciphertext:   base64.RawURLEncoding.EncodeToString(jwe.Ciphertext),
+		Tag:          base64.RawURLEncoding.EncodeToString(jwe.Tag),
+	})
+}
+
+// ParseJWEJSON parses the flattened JWE JSON Serialization produced by
+// JWE.JSON.
+func ParseJWEJSON(data []byte) (*JWE, error) {
+	var raw jweJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("crypto_utils: unmarshal JWE JSON: %w", err)
+	}
+
+	protected, err := base64.RawURLEncoding.DecodeString(raw.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decode protected header: %w", err)
+	}
+	var header Header
+	if err := json.Unmarshal(protected, &header); err != nil {
+		return nil, fmt.Errorf("crypto_utils: unmarshal protected header: %w", err)
+	}
+
+	encryptedKey, err := base64.RawURLEncoding.DecodeString(raw.EncryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decode encrypted key: %w", err)
+	}
+	iv, err := base64.RawURLEncoding.DecodeString(raw.IV)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decode iv: %w", err)
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(raw.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decode ciphertext: %w", err)
+	}
+	tag, err := base64.RawURLEncoding.DecodeString(raw.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decode tag: %w", err)
+	}
+
+	return &JWE{Header: header, EncryptedKey: encryptedKey, IV: iv, Ciphertext: ciphertext, Tag: tag, rawProtected: protected}, nil
+}
+
+// jwsSigningInput returns the JSON-encoded protected header and its
+// base64url form, the first half of the signing input
+// base64url(header) + "." + base64url(payload) that RFC 7515 §5.1 hashes and
+// signs. The payload half is appended by the caller.
+func jwsSigningInput(header Header) ([]byte, string, error) {
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return nil, "", fmt.Errorf("crypto_utils: marshal protected header: %w", err)
+	}
+	return encoded, base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// SignJWSDetached produces a detached RS256 JWS (RFC 7515 Appendix F) over
+// payload: the compact form "header..signature", with the payload segment
+// omitted so the caller can ship payload separately (e.g. alongside a JWE).
+func SignJWSDetached(privateKey *rsa.PrivateKey, payload []byte, header Header) (string, error) {
+	header.Alg = "RS256"
+
+	_, headerB64, err := jwsSigningInput(header)
+	if err != nil {
+		return "", err
+	}
+	payloadB64 := base64.RawURLEncoding.EncodeToString(payload)
+
+	signingInput := headerB64 + "." + payloadB64
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: sign: %w", err)
+	}
+
+	return headerB64 + ".." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyJWSDetached verifies a detached JWS produced by SignJWSDetached
+// against payload.
+func VerifyJWSDetached(publicKey *rsa.PublicKey, payload []byte, compact string) error {
+	parts := strings.Split(compact, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("crypto_utils: compact JWS must have 3 parts, got %d", len(parts))
+	}
+	if parts[1] != "" {
+		return errors.New("crypto_utils: expected a detached JWS with an empty payload segment")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("crypto_utils: decode signature: %w", err)
+	}
+
+	signingInput := parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return fmt.Errorf("crypto_utils: verify: %w", err)
+	}
+	return nil
+}
+
+// Envelope is a JOSE-based replacement for the ad-hoc
+// {payload,key,nonce,signature} JSON message: a compact JWE carrying the
+// encrypted payload, optionally authenticated by a detached JWS over that
+// same compact string.
+type Envelope struct {
+	JWE       string `json:"jwe"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// SealEnvelope encrypts plaintext for encrypter and, if signer is non-nil,
+// attaches a detached RS256 signature over the resulting compact JWE.
+func SealEnvelope(encrypter *JWEEncrypter, plaintext []byte, header Header, signer *rsa.PrivateKey) (*Envelope, error) {
+	jwe, err := encrypter.Encrypt(plaintext, header)
+	if err != nil {
+		return nil, err
+	}
+	compact, err := jwe.Compact()
+	if err != nil {
+		return nil, err
+	}
+
+	env := &Envelope{JWE: compact}
+	if signer != nil {
+		sig, err := SignJWSDetached(signer, []byte(compact), Header{})
+		if err != nil {
+			return nil, err
+		}
+		env.Signature = sig
+	}
+	return env, nil
+}
+
+// Open decrypts the envelope's JWE with decrypter, verifying the detached
+// signature against verifier first when the envelope carries one.
+func (e *Envelope) Open(decrypter *JWEDecrypter, verifier *rsa.PublicKey) ([]byte, error) {
+	if e.Signature != "" {
+		if verifier == nil {
+			return nil, errors.New("crypto_utils: envelope is signed but no verifier key was provided")
+		}
+		if err := VerifyJWSDetached(verifier, []byte(e.JWE), e.Signature); err != nil {
+			return nil, err
+		}
+	}
+	return decrypter.DecryptCompact(e.JWE)
+}
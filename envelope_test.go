@@ -0,0 +1,168 @@
+package crypto_utils_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/sudhi001/crypto_utils"
+)
+
+func generateTestKeyPair(t *testing.T) (*rsa.PrivateKey, *rsa.PublicKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key pair: %v", err)
+	}
+	return key, &key.PublicKey
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	recipient, recipientPub := generateTestKeyPair(t)
+	sender, senderPub := generateTestKeyPair(t)
+
+	plaintext := []byte(`{"Code":"172","Amount":100.0,"Currency":"INR"}`)
+
+	encrypter := crypto_utils.NewJWEEncrypter(recipientPub)
+	envelope, err := crypto_utils.SealEnvelope(encrypter, plaintext, crypto_utils.Header{Kid: "recipient-1"}, sender)
+	if err != nil {
+		t.Fatalf("SealEnvelope failed: %v", err)
+	}
+	if envelope.Signature == "" {
+		t.Fatalf("expected a detached signature on the envelope")
+	}
+
+	decrypter := crypto_utils.NewJWEDecrypter(recipient)
+	decrypted, err := envelope.Open(decrypter, senderPub)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if string(decrypted) != string(plaintext) {
+		t.Fatalf("decrypted mismatch. Expected: %s, Got: %s", plaintext, decrypted)
+	}
+}
+
+func TestEnvelopeRejectsTamperedJWE(t *testing.T) {
+	recipient, recipientPub := generateTestKeyPair(t)
+	sender, senderPub := generateTestKeyPair(t)
+
+	encrypter := crypto_utils.NewJWEEncrypter(recipientPub)
+	envelope, err := crypto_utils.SealEnvelope(encrypter, []byte("hello"), crypto_utils.Header{}, sender)
+	if err != nil {
+		t.Fatalf("SealEnvelope failed: %v", err)
+	}
+
+	envelope.JWE = envelope.JWE + "tampered"
+
+	decrypter := crypto_utils.NewJWEDecrypter(recipient)
+	if _, err := envelope.Open(decrypter, senderPub); err == nil {
+		t.Fatalf("expected Open to fail for a tampered envelope")
+	}
+}
+
+func TestJWECompactRoundTrip(t *testing.T) {
+	recipient, recipientPub := generateTestKeyPair(t)
+
+	encrypter := crypto_utils.NewJWEEncrypter(recipientPub)
+	jwe, err := encrypter.Encrypt([]byte("hello world"), crypto_utils.Header{Kid: "k1"})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	compact, err := jwe.Compact()
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	parsed, err := crypto_utils.ParseJWECompact(compact)
+	if err != nil {
+		t.Fatalf("ParseJWECompact failed: %v", err)
+	}
+
+	decrypter := crypto_utils.NewJWEDecrypter(recipient)
+	plaintext, err := decrypter.Decrypt(parsed)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if string(plaintext) != "hello world" {
+		t.Fatalf("decrypted mismatch. Expected: hello world, Got: %s", plaintext)
+	}
+}
+
+// TestDecryptToleratesNonCanonicalHeaderByteOrder builds a compact JWE by
+// hand, as a non-Go JOSE library would, with its protected header fields in
+// a different order than encoding/json would produce for the same struct.
+// Decrypt must authenticate against the bytes that were actually
+// transmitted, not a re-marshal of the parsed Header.
+func TestDecryptToleratesNonCanonicalHeaderByteOrder(t *testing.T) {
+	recipient, recipientPub := generateTestKeyPair(t)
+
+	rawHeader := `{"kid":"k1","alg":"RSA-OAEP-256","enc":"A256GCM"}`
+	headerB64 := base64.RawURLEncoding.EncodeToString([]byte(rawHeader))
+
+	cek := make([]byte, 32)
+	if _, err := rand.Read(cek); err != nil {
+		t.Fatalf("Failed to generate content encryption key: %v", err)
+	}
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, recipientPub, cek, nil)
+	if err != nil {
+		t.Fatalf("Failed to wrap content encryption key: %v", err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatalf("Failed to init content cipher: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("Failed to init content AEAD: %v", err)
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		t.Fatalf("Failed to generate iv: %v", err)
+	}
+
+	plaintext := []byte("hand-built JWE payload")
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(headerB64))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	compact := strings.Join([]string{
+		headerB64,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, ".")
+
+	decrypter := crypto_utils.NewJWEDecrypter(recipient)
+	decrypted, err := decrypter.DecryptCompact(compact)
+	if err != nil {
+		t.Fatalf("DecryptCompact failed for a non-canonically ordered header: %v", err)
+	}
+	if string(decrypted) != string(plaintext) {
+		t.Fatalf("decrypted mismatch. Expected: %s, Got: %s", plaintext, decrypted)
+	}
+
+	// Re-serializing the parsed JWE must reproduce the exact header bytes it
+	// was sealed under, not a canonical re-marshal, or the round trip breaks.
+	parsed, err := crypto_utils.ParseJWECompact(compact)
+	if err != nil {
+		t.Fatalf("ParseJWECompact failed: %v", err)
+	}
+	reserialized, err := parsed.Compact()
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	redecrypted, err := decrypter.DecryptCompact(reserialized)
+	if err != nil {
+		t.Fatalf("DecryptCompact failed after re-serializing a non-canonical JWE: %v", err)
+	}
+	if string(redecrypted) != string(plaintext) {
+		t.Fatalf("re-serialized decrypted mismatch. Expected: %s, Got: %s", plaintext, redecrypted)
+	}
+}
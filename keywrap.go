@@ -0,0 +1,212 @@
+package crypto_utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDFAlgorithm selects the password-based key derivation function used by
+// WrapPrivateKey.
+type KDFAlgorithm string
+
+const (
+	// KDFScrypt derives the wrapping key with scrypt (the default).
+	KDFScrypt KDFAlgorithm = "scrypt"
+	// KDFPBKDF2 derives the wrapping key with PBKDF2-HMAC-SHA256.
+	KDFPBKDF2 KDFAlgorithm = "pbkdf2-sha256"
+)
+
+// KDFOptions configures WrapPrivateKey's key derivation. The zero value
+// selects scrypt with N=32768, r=8, p=1, matching common at-rest defaults.
+type KDFOptions struct {
+	KDF KDFAlgorithm
+	N   int // scrypt CPU/memory cost, or PBKDF2 iteration count
+	R   int // scrypt block size
+	P   int // scrypt parallelization
+}
+
+func (o KDFOptions) withDefaults() KDFOptions {
+	if o.KDF == "" {
+		o.KDF = KDFScrypt
+	}
+	if o.N == 0 {
+		if o.KDF == KDFPBKDF2 {
+			o.N = 210000
+		} else {
+			o.N = 32768
+		}
+	}
+	if o.R == 0 {
+		o.R = 8
+	}
+	if o.P == 0 {
+		o.P = 1
+	}
+	return o
+}
+
+// wrappedKeyVersion is the "v" field of the wrapped blob, bumped if the
+// container format ever changes incompatibly.
+const wrappedKeyVersion = 1
+
+const wrapSaltSize = 16
+
+// wrappedKey is the self-describing JSON container WrapPrivateKey produces.
+type wrappedKey struct {
+	V          int    `json:"v"`
+	KDF        string `json:"kdf"`
+	Salt       string `json:"salt"`
+	N          int    `json:"N"`
+	R          int    `json:"r,omitempty"`
+	P          int    `json:"p,omitempty"`
+	IV         string `json:"iv"`
+	Ciphertext string `json:"ciphertext"`
+	Tag        string `json:"tag"`
+}
+
+// WrapPrivateKey encrypts privateKeyB64 (as returned by GenerateKeyPair) at
+// rest under a key derived from password via opts' KDF, returning a
+// self-describing JSON blob safe to persist to disk or Flutter secure
+// storage. UnwrapPrivateKey reverses it.
+func WrapPrivateKey(privateKeyB64 string, password string, opts KDFOptions) (string, error) {
+	opts = opts.withDefaults()
+
+	salt := make([]byte, wrapSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("crypto_utils: generate salt: %w", err)
+	}
+
+	key, err := deriveWrapKey(opts, []byte(password), salt)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := aesGCM(key)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: init AES-GCM: %w", err)
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("crypto_utils: generate iv: %w", err)
+	}
+
+	sealed := gcm.Seal(nil, iv, []byte(privateKeyB64), nil)
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	blob := wrappedKey{
+		V:          wrappedKeyVersion,
+		KDF:        string(opts.KDF),
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		N:          opts.N,
+		R:          opts.R,
+		P:          opts.P,
+		IV:         base64.StdEncoding.EncodeToString(iv),
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+		Tag:        base64.StdEncoding.EncodeToString(tag),
+	}
+	// scrypt's r/p only make sense alongside scrypt's N; omit them for PBKDF2
+	// so the blob doesn't imply parameters that were never used.
+	if opts.KDF != KDFScrypt {
+		blob.R, blob.P = 0, 0
+	}
+
+	data, err := json.Marshal(blob)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: marshal wrapped key: %w", err)
+	}
+	return string(data), nil
+}
+
+// UnwrapPrivateKey reverses WrapPrivateKey, returning the original
+// privateKeyB64. It returns an error for a wrong password, a corrupted
+// blob, or an unsupported KDF/version.
+func UnwrapPrivateKey(wrapped string, password string) (string, error) {
+	var blob wrappedKey
+	if err := json.Unmarshal([]byte(wrapped), &blob); err != nil {
+		return "", fmt.Errorf("crypto_utils: unmarshal wrapped key: %w", err)
+	}
+	if blob.V != wrappedKeyVersion {
+		return "", fmt.Errorf("crypto_utils: unsupported wrapped key version %d", blob.V)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(blob.Salt)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: decode salt: %w", err)
+	}
+
+	opts := KDFOptions{KDF: KDFAlgorithm(blob.KDF), N: blob.N, R: blob.R, P: blob.P}.withDefaults()
+	key, err := deriveWrapKey(opts, []byte(password), salt)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := aesGCM(key)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: init AES-GCM: %w", err)
+	}
+
+	iv, err := base64.StdEncoding.DecodeString(blob.IV)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: decode iv: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(blob.Ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: decode ciphertext: %w", err)
+	}
+	tag, err := base64.StdEncoding.DecodeString(blob.Tag)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: decode tag: %w", err)
+	}
+
+	sealed := append(append([]byte{}, ciphertext...), tag...)
+	plaintext, err := gcm.Open(nil, iv, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: wrong password or corrupted blob: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// CheckPassword reports whether password unwraps wrapped. The comparison
+// that matters — the AES-GCM authentication tag — is checked in constant
+// time by crypto/cipher itself (via subtle.ConstantTimeCompare), so this is
+// no less safe against timing attacks than UnwrapPrivateKey.
+func CheckPassword(wrapped string, password string) bool {
+	_, err := UnwrapPrivateKey(wrapped, password)
+	return err == nil
+}
+
+// Rewrap decrypts wrapped with oldPassword and re-wraps the same private
+// key under newPassword (optionally with new KDF parameters), for password
+// rotation without ever persisting the key unwrapped.
+func Rewrap(wrapped string, oldPassword string, newPassword string, opts KDFOptions) (string, error) {
+	privateKeyB64, err := UnwrapPrivateKey(wrapped, oldPassword)
+	if err != nil {
+		return "", err
+	}
+	return WrapPrivateKey(privateKeyB64, newPassword, opts)
+}
+
+// deriveWrapKey stretches password into an AES-256 key per opts' KDF.
+func deriveWrapKey(opts KDFOptions, password []byte, salt []byte) ([]byte, error) {
+	switch opts.KDF {
+	case KDFScrypt, "":
+		key, err := scrypt.Key(password, salt, opts.N, opts.R, opts.P, 32)
+		if err != nil {
+			return nil, fmt.Errorf("crypto_utils: scrypt: %w", err)
+		}
+		return key, nil
+
+	case KDFPBKDF2:
+		return pbkdf2.Key(password, salt, opts.N, 32, sha256.New), nil
+
+	default:
+		return nil, fmt.Errorf("crypto_utils: unsupported KDF %q", opts.KDF)
+	}
+}
@@ -0,0 +1,107 @@
+package crypto_utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sudhi001/crypto_utils"
+)
+
+func TestWrapUnwrapPrivateKeyRoundTrip(t *testing.T) {
+	util := crypto_utils.NewCryptoUtils()
+	privateKeyB64, _, err := util.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+
+	wrapped, err := crypto_utils.WrapPrivateKey(privateKeyB64, "correct horse battery staple", crypto_utils.KDFOptions{})
+	if err != nil {
+		t.Fatalf("WrapPrivateKey failed: %v", err)
+	}
+	if strings.Contains(wrapped, privateKeyB64) {
+		t.Fatalf("wrapped blob must not contain the private key in the clear")
+	}
+
+	unwrapped, err := crypto_utils.UnwrapPrivateKey(wrapped, "correct horse battery staple")
+	if err != nil {
+		t.Fatalf("UnwrapPrivateKey failed: %v", err)
+	}
+	if unwrapped != privateKeyB64 {
+		t.Fatalf("unwrapped key mismatch. Expected: %s, Got: %s", privateKeyB64, unwrapped)
+	}
+
+	if _, err := crypto_utils.UnwrapPrivateKey(wrapped, "wrong password"); err == nil {
+		t.Fatalf("expected UnwrapPrivateKey to fail with the wrong password")
+	}
+}
+
+func TestWrapPrivateKeyWithPBKDF2(t *testing.T) {
+	util := crypto_utils.NewCryptoUtils()
+	privateKeyB64, _, err := util.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+
+	wrapped, err := crypto_utils.WrapPrivateKey(privateKeyB64, "hunter2", crypto_utils.KDFOptions{KDF: crypto_utils.KDFPBKDF2, N: 1000})
+	if err != nil {
+		t.Fatalf("WrapPrivateKey failed: %v", err)
+	}
+
+	unwrapped, err := crypto_utils.UnwrapPrivateKey(wrapped, "hunter2")
+	if err != nil {
+		t.Fatalf("UnwrapPrivateKey failed: %v", err)
+	}
+	if unwrapped != privateKeyB64 {
+		t.Fatalf("unwrapped key mismatch. Expected: %s, Got: %s", privateKeyB64, unwrapped)
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	util := crypto_utils.NewCryptoUtils()
+	privateKeyB64, _, err := util.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+
+	wrapped, err := crypto_utils.WrapPrivateKey(privateKeyB64, "s3cr3t", crypto_utils.KDFOptions{N: 1024})
+	if err != nil {
+		t.Fatalf("WrapPrivateKey failed: %v", err)
+	}
+
+	if !crypto_utils.CheckPassword(wrapped, "s3cr3t") {
+		t.Fatalf("expected CheckPassword to succeed with the correct password")
+	}
+	if crypto_utils.CheckPassword(wrapped, "not-it") {
+		t.Fatalf("expected CheckPassword to fail with the wrong password")
+	}
+}
+
+func TestRewrapRotatesPassword(t *testing.T) {
+	util := crypto_utils.NewCryptoUtils()
+	privateKeyB64, _, err := util.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+
+	wrapped, err := crypto_utils.WrapPrivateKey(privateKeyB64, "old-password", crypto_utils.KDFOptions{N: 1024})
+	if err != nil {
+		t.Fatalf("WrapPrivateKey failed: %v", err)
+	}
+
+	rewrapped, err := crypto_utils.Rewrap(wrapped, "old-password", "new-password", crypto_utils.KDFOptions{N: 1024})
+	if err != nil {
+		t.Fatalf("Rewrap failed: %v", err)
+	}
+
+	if _, err := crypto_utils.UnwrapPrivateKey(rewrapped, "old-password"); err == nil {
+		t.Fatalf("expected the old password to no longer unwrap the rotated blob")
+	}
+
+	unwrapped, err := crypto_utils.UnwrapPrivateKey(rewrapped, "new-password")
+	if err != nil {
+		t.Fatalf("UnwrapPrivateKey with the new password failed: %v", err)
+	}
+	if unwrapped != privateKeyB64 {
+		t.Fatalf("unwrapped key mismatch. Expected: %s, Got: %s", privateKeyB64, unwrapped)
+	}
+}
@@ -0,0 +1,141 @@
+package crypto_utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// PublicKeyToPEM encodes publicKey as a PKIX "PUBLIC KEY" PEM block, the
+// format `openssl` and most other RSA/ECDSA/Ed25519 tooling produce.
+func PublicKeyToPEM(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: marshal public key: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// PrivateKeyToPEM encodes privateKey as a PEM block: PKCS#1 "RSA PRIVATE
+// KEY" for *rsa.PrivateKey, SEC1 "EC PRIVATE KEY" for *ecdsa.PrivateKey, and
+// PKCS#8 "PRIVATE KEY" for ed25519.PrivateKey (Ed25519 has no legacy
+// PKCS#1-style container).
+func PrivateKeyToPEM(privateKey crypto.Signer) (string, error) {
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return string(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})), nil
+
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return "", fmt.Errorf("crypto_utils: marshal EC private key: %w", err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), nil
+
+	case ed25519.PrivateKey:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return "", fmt.Errorf("crypto_utils: marshal PKCS#8 private key: %w", err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+
+	default:
+		return "", fmt.Errorf("crypto_utils: unsupported private key type %T", privateKey)
+	}
+}
+
+// PEMToPublicKey decodes a "PUBLIC KEY" (PKIX) or "RSA PUBLIC KEY" (PKCS#1)
+// PEM block, as produced by `openssl` or PublicKeyToPEM.
+func PEMToPublicKey(pemStr string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, fmt.Errorf("crypto_utils: no PEM block found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("crypto_utils: unsupported PEM block type %q for a public key", block.Type)
+	}
+}
+
+// PEMToPrivateKey decodes an unencrypted "RSA PRIVATE KEY" (PKCS#1), "EC
+// PRIVATE KEY" (SEC1), or "PRIVATE KEY" (PKCS#8) PEM block. For "ENCRYPTED
+// PRIVATE KEY" blocks, use PEMToPrivateKeyEncrypted with the passphrase.
+func PEMToPrivateKey(pemStr string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, fmt.Errorf("crypto_utils: no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("crypto_utils: parse PKCS#8 private key: %w", err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("crypto_utils: PKCS#8 key of type %T does not implement crypto.Signer", key)
+		}
+		return signer, nil
+
+	case "ENCRYPTED PRIVATE KEY":
+		return nil, fmt.Errorf("crypto_utils: %q blocks are password-protected; use PEMToPrivateKeyEncrypted", block.Type)
+
+	default:
+		return nil, fmt.Errorf("crypto_utils: unsupported PEM block type %q for a private key", block.Type)
+	}
+}
+
+// ParseAnyPublicKey auto-detects and parses a public key pasted from
+// openssl (PEM), ssh-keygen (an `authorized_keys` line), or another
+// crypto_utils instance (tagged base64, see algorithm.go), falling back to
+// plain base64 DER (PKIX or PKCS#1) for keys generated before algorithm
+// tagging was introduced.
+func ParseAnyPublicKey(input string) (crypto.PublicKey, error) {
+	trimmed := strings.TrimSpace(input)
+
+	switch {
+	case strings.HasPrefix(trimmed, "-----BEGIN"):
+		return PEMToPublicKey(trimmed)
+
+	case looksLikeSSHAuthorizedKey(trimmed):
+		return SSHAuthorizedKeyToPublicKey(trimmed)
+
+	default:
+		if _, publicKey, err := parsePublicKeyB64(trimmed); err == nil {
+			return publicKey, nil
+		}
+
+		der, err := base64.StdEncoding.DecodeString(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("crypto_utils: input is not PEM, an SSH authorized key, or base64: %w", err)
+		}
+		if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+			return key, nil
+		}
+		if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+			return key, nil
+		}
+		return nil, fmt.Errorf("crypto_utils: could not parse %q as any known public key format", trimmed)
+	}
+}
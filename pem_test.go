@@ -0,0 +1,278 @@
+package crypto_utils_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"hash"
+	"testing"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/sudhi001/crypto_utils"
+)
+
+func TestPublicKeyPEMRoundTrip(t *testing.T) {
+	util := crypto_utils.NewCryptoUtils()
+	_, publicKeyB64, err := util.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+	publicKey, err := util.Base64ToPublicKey(publicKeyB64)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+
+	pemStr, err := crypto_utils.PublicKeyToPEM(publicKey)
+	if err != nil {
+		t.Fatalf("PublicKeyToPEM failed: %v", err)
+	}
+
+	parsed, err := crypto_utils.PEMToPublicKey(pemStr)
+	if err != nil {
+		t.Fatalf("PEMToPublicKey failed: %v", err)
+	}
+	if !parsed.(*rsa.PublicKey).Equal(publicKey.(*rsa.PublicKey)) {
+		t.Fatalf("round-tripped public key does not match the original")
+	}
+}
+
+func TestPrivateKeyPEMRoundTrip(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	pemStr, err := crypto_utils.PrivateKeyToPEM(key)
+	if err != nil {
+		t.Fatalf("PrivateKeyToPEM failed: %v", err)
+	}
+
+	parsed, err := crypto_utils.PEMToPrivateKey(pemStr)
+	if err != nil {
+		t.Fatalf("PEMToPrivateKey failed: %v", err)
+	}
+	if !parsed.(*rsa.PrivateKey).Equal(key) {
+		t.Fatalf("round-tripped private key does not match the original")
+	}
+}
+
+func TestSSHAuthorizedKeyRoundTrip(t *testing.T) {
+	util := crypto_utils.NewCryptoUtils()
+	_, publicKeyB64, err := util.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+	publicKey, err := util.Base64ToPublicKey(publicKeyB64)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+
+	authorizedKey, err := crypto_utils.PublicKeyToSSHAuthorizedKey(publicKey, "user@host")
+	if err != nil {
+		t.Fatalf("PublicKeyToSSHAuthorizedKey failed: %v", err)
+	}
+
+	parsed, err := crypto_utils.SSHAuthorizedKeyToPublicKey(authorizedKey)
+	if err != nil {
+		t.Fatalf("SSHAuthorizedKeyToPublicKey failed: %v", err)
+	}
+	if !parsed.(*rsa.PublicKey).Equal(publicKey.(*rsa.PublicKey)) {
+		t.Fatalf("round-tripped SSH public key does not match the original")
+	}
+}
+
+func TestParseAnyPublicKeyDetectsEveryFormat(t *testing.T) {
+	util := crypto_utils.NewCryptoUtils()
+	_, publicKeyB64, err := util.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+	publicKey, err := util.Base64ToPublicKey(publicKeyB64)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+
+	pemStr, err := crypto_utils.PublicKeyToPEM(publicKey)
+	if err != nil {
+		t.Fatalf("PublicKeyToPEM failed: %v", err)
+	}
+	authorizedKey, err := crypto_utils.PublicKeyToSSHAuthorizedKey(publicKey, "")
+	if err != nil {
+		t.Fatalf("PublicKeyToSSHAuthorizedKey failed: %v", err)
+	}
+
+	cases := map[string]string{
+		"crypto_utils tagged base64": publicKeyB64,
+		"PEM":                        pemStr,
+		"SSH authorized_keys":        authorizedKey,
+	}
+	for name, input := range cases {
+		parsed, err := crypto_utils.ParseAnyPublicKey(input)
+		if err != nil {
+			t.Fatalf("ParseAnyPublicKey(%s) failed: %v", name, err)
+		}
+		if !parsed.(*rsa.PublicKey).Equal(publicKey.(*rsa.PublicKey)) {
+			t.Fatalf("ParseAnyPublicKey(%s) returned a mismatched key", name)
+		}
+	}
+}
+
+func TestPEMToPrivateKeyRejectsEncryptedBlockWithoutPassword(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	pemStr := encryptPKCS8ForTest(t, key, "s3cr3t", sha256.New, false)
+
+	if _, err := crypto_utils.PEMToPrivateKey(pemStr); err == nil {
+		t.Fatalf("expected PEMToPrivateKey to reject an ENCRYPTED PRIVATE KEY block")
+	}
+}
+
+func TestPEMToPrivateKeyEncryptedRoundTrip(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	pemStr := encryptPKCS8ForTest(t, key, "s3cr3t", sha256.New, false)
+
+	signer, err := crypto_utils.PEMToPrivateKeyEncrypted(pemStr, "s3cr3t")
+	if err != nil {
+		t.Fatalf("PEMToPrivateKeyEncrypted failed: %v", err)
+	}
+	if !signer.(*rsa.PrivateKey).Equal(key) {
+		t.Fatalf("decrypted private key does not match the original")
+	}
+
+	if _, err := crypto_utils.PEMToPrivateKeyEncrypted(pemStr, "wrong password"); err == nil {
+		t.Fatalf("expected PEMToPrivateKeyEncrypted to fail with the wrong password")
+	}
+}
+
+// TestPEMToPrivateKeyEncryptedDefaultsPRFToSHA1 covers RFC 8018 Appendix
+// A.2: when the PBKDF2 PRF AlgorithmIdentifier is omitted from the ASN.1 (a
+// spec-legal encoding some tools produce), the default PRF is HMAC-SHA1, not
+// HMAC-SHA256.
+func TestPEMToPrivateKeyEncryptedDefaultsPRFToSHA1(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	pemStr := encryptPKCS8ForTest(t, key, "s3cr3t", sha1.New, true)
+
+	signer, err := crypto_utils.PEMToPrivateKeyEncrypted(pemStr, "s3cr3t")
+	if err != nil {
+		t.Fatalf("PEMToPrivateKeyEncrypted failed with an omitted PRF: %v", err)
+	}
+	if !signer.(*rsa.PrivateKey).Equal(key) {
+		t.Fatalf("decrypted private key does not match the original")
+	}
+}
+
+// encryptPKCS8ForTest builds a minimal PBES2/PBKDF2/AES-256-CBC "ENCRYPTED
+// PRIVATE KEY" PEM block, matching what `openssl pkcs8 -topk8` produces,
+// since the standard library cannot itself emit one. When omitPRF is true,
+// the PBKDF2 PRF field is left out of the ASN.1, as RFC 8018 allows, to
+// exercise its default-to-HMAC-SHA1 behavior.
+func encryptPKCS8ForTest(t *testing.T, key *rsa.PrivateKey, password string, prf func() hash.Hash, omitPRF bool) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey failed: %v", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		t.Fatalf("Failed to generate salt: %v", err)
+	}
+	iterationCount := 2048
+	derivedKey := pbkdf2.Key([]byte(password), salt, iterationCount, 32, prf)
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		t.Fatalf("Failed to generate iv: %v", err)
+	}
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		t.Fatalf("Failed to init AES cipher: %v", err)
+	}
+	padded := pkcs7PadForTest(der, aes.BlockSize)
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+
+	var kdfParamsDER []byte
+	if omitPRF {
+		kdfParamsDER, err = asn1.Marshal(struct {
+			Salt           []byte
+			IterationCount int
+		}{salt, iterationCount})
+	} else {
+		prfOID := asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9} // hmacWithSHA256
+		kdfParamsDER, err = asn1.Marshal(struct {
+			Salt           []byte
+			IterationCount int
+			KeyLength      int
+			PRF            pkix.AlgorithmIdentifier
+		}{salt, iterationCount, 32, pkix.AlgorithmIdentifier{Algorithm: prfOID, Parameters: asn1.NullRawValue}})
+	}
+	if err != nil {
+		t.Fatalf("Failed to marshal PBKDF2 params: %v", err)
+	}
+
+	ivDER, err := asn1.Marshal(iv)
+	if err != nil {
+		t.Fatalf("Failed to marshal IV: %v", err)
+	}
+
+	pbes2ParamsDER, err := asn1.Marshal(struct {
+		KeyDerivationFunc pkix.AlgorithmIdentifier
+		EncryptionScheme  pkix.AlgorithmIdentifier
+	}{
+		pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}, // pbkdf2
+			Parameters: asn1.RawValue{FullBytes: kdfParamsDER},
+		},
+		pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}, // aes256-CBC
+			Parameters: asn1.RawValue{FullBytes: ivDER},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal PBES2 params: %v", err)
+	}
+
+	infoDER, err := asn1.Marshal(struct {
+		Algo          pkix.AlgorithmIdentifier
+		EncryptedData []byte
+	}{
+		pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}, // pbes2
+			Parameters: asn1.RawValue{FullBytes: pbes2ParamsDER},
+		},
+		ciphertext,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal EncryptedPrivateKeyInfo: %v", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: infoDER}))
+}
+
+func pkcs7PadForTest(data []byte, blockSize int) []byte {
+	padLen := blockSize - len(data)%blockSize
+	padded := make([]byte, len(data)+padLen)
+	copy(padded, data)
+	for i := len(data); i < len(padded); i++ {
+		padded[i] = byte(padLen)
+	}
+	return padded
+}
@@ -0,0 +1,60 @@
+package crypto_utils
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sshAuthorizedKeyPrefixes are the algorithm names authorized_keys lines
+// (and SSH public key files) start with, used by ParseAnyPublicKey to
+// distinguish them from base64 DER or PEM input.
+var sshAuthorizedKeyPrefixes = []string{
+	"ssh-rsa", "ssh-ed25519", "ssh-dss",
+	"ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521",
+}
+
+func looksLikeSSHAuthorizedKey(input string) bool {
+	for _, prefix := range sshAuthorizedKeyPrefixes {
+		if strings.HasPrefix(input, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicKeyToSSHAuthorizedKey renders publicKey as an OpenSSH
+// `authorized_keys` line (as `ssh-keygen -y` would produce), optionally
+// followed by a trailing comment.
+func PublicKeyToSSHAuthorizedKey(publicKey crypto.PublicKey, comment string) (string, error) {
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: convert to SSH public key: %w", err)
+	}
+
+	line := bytes.TrimRight(ssh.MarshalAuthorizedKey(sshPublicKey), "\n")
+	if comment != "" {
+		line = append(line, ' ')
+		line = append(line, comment...)
+	}
+	return string(line), nil
+}
+
+// SSHAuthorizedKeyToPublicKey parses a single OpenSSH `authorized_keys`
+// line into the corresponding crypto.PublicKey (*rsa.PublicKey,
+// *ecdsa.PublicKey, or ed25519.PublicKey).
+func SSHAuthorizedKeyToPublicKey(authorizedKey string) (crypto.PublicKey, error) {
+	sshPublicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: parse SSH authorized key: %w", err)
+	}
+
+	cryptoPublicKey, ok := sshPublicKey.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, fmt.Errorf("crypto_utils: SSH key type %q has no crypto.PublicKey equivalent", sshPublicKey.Type())
+	}
+	return cryptoPublicKey.CryptoPublicKey(), nil
+}
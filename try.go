@@ -0,0 +1,135 @@
+package crypto_utils
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+)
+
+// OAEPOptions configures the RSA-OAEP hash and label used by
+// EncryptWithPublicKeyOpts/DecryptWithPrivateKeyOpts. Hash defaults to
+// crypto.SHA256 if left zero. Label must match on both ends.
+type OAEPOptions struct {
+	Hash  crypto.Hash
+	Label []byte
+}
+
+func (o OAEPOptions) hash() crypto.Hash {
+	if o.Hash == 0 {
+		return crypto.SHA256
+	}
+	return o.Hash
+}
+
+// EncryptWithPublicKeyOpts RSA-OAEP encrypts data under publicKey using
+// opts' hash and label, returning the base64-encoded ciphertext. Unlike
+// EncryptWithPublicKey it never panics and only supports RSA keys; callers
+// negotiating with an older Dart/JS RSA library that defaults to SHA-1 OAEP
+// can set opts.Hash accordingly.
+func (c *CryptoUtils) EncryptWithPublicKeyOpts(publicKey crypto.PublicKey, data []byte, opts OAEPOptions) (string, error) {
+	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("crypto_utils: EncryptWithPublicKeyOpts only supports RSA keys, got %T", publicKey)
+	}
+
+	ciphertext, err := rsa.EncryptOAEP(opts.hash().New(), rand.Reader, rsaPublicKey, data, opts.Label)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: encrypt: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptWithPrivateKeyOpts reverses EncryptWithPublicKeyOpts. opts must
+// match the hash and label used to encrypt.
+func (c *CryptoUtils) DecryptWithPrivateKeyOpts(privateKeyB64 string, encryptedB64 string, opts OAEPOptions) ([]byte, error) {
+	alg, signer, err := parsePrivateKeyAsSigner(privateKeyB64)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: parse private key: %w", err)
+	}
+	if alg != RSA2048 && alg != RSA3072 && alg != RSA4096 {
+		return nil, fmt.Errorf("crypto_utils: DecryptWithPrivateKeyOpts only supports RSA keys, got %s", alg)
+	}
+	rsaPrivateKey := signer.(*rsa.PrivateKey)
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encryptedB64)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decode ciphertext: %w", err)
+	}
+
+	plaintext, err := rsa.DecryptOAEP(opts.hash().New(), rand.Reader, rsaPrivateKey, ciphertext, opts.Label)
+	if err != nil {
+		return nil, fmt.Errorf("crypto_utils: decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+// TryEncryptWithAES is the error-returning sibling of EncryptWithAES, for
+// callers (e.g. server code handling client-supplied keys) that must not
+// crash on malformed input.
+func (c *CryptoUtils) TryEncryptWithAES(key []byte, plaintext []byte) (string, []byte, error) {
+	gcm, err := aesGCM(key)
+	if err != nil {
+		return "", nil, fmt.Errorf("crypto_utils: init AES-GCM: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, fmt.Errorf("crypto_utils: generate nonce: %w", err)
+	}
+
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(ciphertext), nonce, nil
+}
+
+// TryDecryptWithAES is the error-returning sibling of DecryptWithAES.
+func (c *CryptoUtils) TryDecryptWithAES(key []byte, ciphertext []byte, nonce []byte) (string, error) {
+	gcm, err := aesGCM(key)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: init AES-GCM: %w", err)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(string(ciphertext))
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: decode ciphertext: %w", err)
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, raw, nil)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: open: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// TrySignWithPrivateKey is the error-returning sibling of SignWithPrivateKey.
+func (c *CryptoUtils) TrySignWithPrivateKey(privateKeyB64 string, data []byte) (string, error) {
+	_, signer, err := parsePrivateKeyAsSigner(privateKeyB64)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: parse private key: %w", err)
+	}
+
+	signature, err := signWithSigner(signer, data)
+	if err != nil {
+		return "", fmt.Errorf("crypto_utils: sign: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// TryVerifyWithPublicKey is the error-returning sibling of
+// VerifyWithPublicKey: it returns a non-nil error only when publicKeyB64 or
+// signatureB64 can't be parsed, so callers can tell that apart from a
+// genuine signature mismatch (ok == false, err == nil).
+func (c *CryptoUtils) TryVerifyWithPublicKey(publicKeyB64 string, data []byte, signatureB64 string) (bool, error) {
+	_, publicKey, err := parsePublicKeyB64(publicKeyB64)
+	if err != nil {
+		return false, fmt.Errorf("crypto_utils: parse public key: %w", err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(signatureB64)
+	if err != nil {
+		return false, fmt.Errorf("crypto_utils: decode signature: %w", err)
+	}
+
+	return verifyWithPublicKey(publicKey, data, signature), nil
+}
@@ -0,0 +1,107 @@
+package crypto_utils_test
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/sudhi001/crypto_utils"
+)
+
+func TestTryDecryptWithAESReturnsErrorOnWrongKey(t *testing.T) {
+	util := crypto_utils.NewCryptoUtils()
+
+	correctKey, err := util.GenerateRandomBytes(32)
+	if err != nil {
+		t.Fatalf("Failed to generate random AES key: %v", err)
+	}
+	wrongKey, err := util.GenerateRandomBytes(32)
+	if err != nil {
+		t.Fatalf("Failed to generate random wrong AES key: %v", err)
+	}
+
+	plaintext := `{"Code":"172","Amount":100.0,"Currency":"INR"}`
+	ciphertext, nonce, err := util.TryEncryptWithAES(correctKey, []byte(plaintext))
+	if err != nil {
+		t.Fatalf("TryEncryptWithAES failed: %v", err)
+	}
+
+	if _, err := util.TryDecryptWithAES(wrongKey, []byte(ciphertext), nonce); err == nil {
+		t.Fatalf("expected TryDecryptWithAES to return an error for the wrong key")
+	}
+
+	decrypted, err := util.TryDecryptWithAES(correctKey, []byte(ciphertext), nonce)
+	if err != nil {
+		t.Fatalf("TryDecryptWithAES failed with the correct key: %v", err)
+	}
+	if decrypted != plaintext {
+		t.Fatalf("decrypted mismatch. Expected: %s, Got: %s", plaintext, decrypted)
+	}
+}
+
+func TestTrySignAndVerifyWithPrivateKey(t *testing.T) {
+	util := crypto_utils.NewCryptoUtils()
+
+	privateKey, publicKey, err := util.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+
+	message := []byte("hello")
+	signature, err := util.TrySignWithPrivateKey(privateKey, message)
+	if err != nil {
+		t.Fatalf("TrySignWithPrivateKey failed: %v", err)
+	}
+
+	ok, err := util.TryVerifyWithPublicKey(publicKey, message, signature)
+	if err != nil {
+		t.Fatalf("TryVerifyWithPublicKey returned an unexpected error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("expected the signature to verify")
+	}
+
+	ok, err = util.TryVerifyWithPublicKey(publicKey, []byte("tampered"), signature)
+	if err != nil {
+		t.Fatalf("TryVerifyWithPublicKey returned an unexpected error for a mismatch: %v", err)
+	}
+	if ok {
+		t.Fatalf("expected the signature to fail verification for a different message")
+	}
+
+	if _, err := util.TryVerifyWithPublicKey("not-base64!!", message, signature); err == nil {
+		t.Fatalf("expected TryVerifyWithPublicKey to return an error for a malformed public key")
+	}
+}
+
+func TestEncryptWithPublicKeyOptsSHA1Interop(t *testing.T) {
+	util := crypto_utils.NewCryptoUtils()
+
+	privateKey, publicKeyB64, err := util.GenerateRSAKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate compatible RSA key pair: %v", err)
+	}
+	publicKey, err := util.Base64ToPublicKey(publicKeyB64)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+
+	plaintext := []byte("legacy client payload")
+	opts := crypto_utils.OAEPOptions{Hash: crypto.SHA1}
+
+	encrypted, err := util.EncryptWithPublicKeyOpts(publicKey, plaintext, opts)
+	if err != nil {
+		t.Fatalf("EncryptWithPublicKeyOpts failed: %v", err)
+	}
+
+	decrypted, err := util.DecryptWithPrivateKeyOpts(privateKey, encrypted, opts)
+	if err != nil {
+		t.Fatalf("DecryptWithPrivateKeyOpts failed: %v", err)
+	}
+	if string(decrypted) != string(plaintext) {
+		t.Fatalf("decrypted mismatch. Expected: %s, Got: %s", plaintext, decrypted)
+	}
+
+	if _, err := util.DecryptWithPrivateKeyOpts(privateKey, encrypted, crypto_utils.OAEPOptions{Hash: crypto.SHA256}); err == nil {
+		t.Fatalf("expected decryption to fail when the OAEP hash doesn't match")
+	}
+}